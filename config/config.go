@@ -0,0 +1,126 @@
+// Package config holds the parsed representation of a pathvector config
+// file. It is kept separate from package main so that both main and the
+// processors package can operate on the same Peer/Config types without
+// introducing an import cycle.
+package config
+
+import (
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Peer is a single configured BGP session and everything the processor
+// pipeline discovers or generates about it.
+type Peer struct {
+	Asn         uint32 `yaml:"asn"`
+	Type        string `yaml:"type"`
+	AsSet       string `yaml:"as-set"`
+	NoPeeringDB bool   `yaml:"no-peeringdb"`
+
+	ImportLimit4 int `yaml:"import-limit4"`
+	ImportLimit6 int `yaml:"import-limit6"`
+
+	MD5Password string `yaml:"md5-password"`
+	BfdAuthKey  string `yaml:"bfd-auth-key"`
+
+	// Name is the peer's config key, e.g. its map key in Config.Peers.
+	Name string `yaml:"-"`
+
+	// ProtocolName is the sanitized BIRD protocol name derived from the
+	// peer's config key.
+	ProtocolName string `yaml:"-"`
+
+	// PrefixSet4/6 are the resolved IRR/RPKI-filtered prefix lists used
+	// to render the peer's import filter.
+	PrefixSet4 []string `yaml:"-"`
+	PrefixSet6 []string `yaml:"-"`
+
+	// Candidates4/6 are the raw IRR-resolved (prefix, origin) pairs,
+	// populated by the irr processor and consumed by the rpki processor
+	// before the survivors are written back into PrefixSet4/6.
+	Candidates4 []PrefixOrigin `yaml:"-"`
+	Candidates6 []PrefixOrigin `yaml:"-"`
+
+	// RpkiInvalidCount is the number of candidate prefixes dropped by
+	// the rpki processor on the most recent run.
+	RpkiInvalidCount int `yaml:"-"`
+
+	SessionGlobal  string `yaml:"-"`
+	PreImport      string `yaml:"-"`
+	PreExport      string `yaml:"-"`
+	PreImportFinal string `yaml:"-"`
+	PreExportFinal string `yaml:"-"`
+
+	QueryTime string `yaml:"-"`
+
+	// LastStatus and LastProcessed are set by the processor pipeline and
+	// surfaced by the statusz endpoint.
+	LastStatus    string    `yaml:"-"`
+	LastProcessed time.Time `yaml:"-"`
+}
+
+// PrefixOrigin is a candidate prefix together with the ASN it was
+// learned from, the unit the rpki processor validates.
+type PrefixOrigin struct {
+	Prefix string
+	Origin uint32
+}
+
+// Config is the top-level parsed pathvector config file.
+type Config struct {
+	BirdDirectory  string `yaml:"bird-directory"`
+	BirdSocket     string `yaml:"bird-socket"`
+	CacheDirectory string `yaml:"cache-directory"`
+
+	IrrDb          string   `yaml:"irrdb"`
+	IrrServer      string   `yaml:"irr-server"`
+	IrrSourceOrder []string `yaml:"irr-source-order"`
+
+	MaxPrefixLen4 int `yaml:"max-prefix-len4"`
+	MaxPrefixLen6 int `yaml:"max-prefix-len6"`
+
+	// IrrCacheTtl controls how long a resolved AS-SET is reused from the
+	// on-disk IRR cache before it's re-queried.
+	IrrCacheTtl time.Duration `yaml:"irr-cache-ttl"`
+
+	RtrServer  string `yaml:"rtr-server"`
+	RpkiRoaUrl string `yaml:"rpki-roa-url"`
+
+	Peers map[string]*Peer `yaml:"peers"`
+
+	// ConfigPath and ConfigModTime are not read from YAML; the caller
+	// of Load sets them for statusz reporting.
+	ConfigPath    string    `yaml:"-"`
+	ConfigModTime time.Time `yaml:"-"`
+}
+
+// Load parses a pathvector config file from its raw YAML bytes.
+func Load(b []byte) (*Config, error) {
+	var c Config
+	if err := yaml.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+
+	if c.MaxPrefixLen4 == 0 {
+		c.MaxPrefixLen4 = 24
+	}
+	if c.MaxPrefixLen6 == 0 {
+		c.MaxPrefixLen6 = 48
+	}
+	if c.IrrServer == "" {
+		c.IrrServer = "rr.ntt.net"
+	}
+	if c.IrrCacheTtl == 0 {
+		c.IrrCacheTtl = 24 * time.Hour
+	}
+
+	for name, p := range c.Peers {
+		if p.Type == "" {
+			p.Type = "peer"
+		}
+		_ = name
+	}
+
+	return &c, nil
+}
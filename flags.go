@@ -0,0 +1,29 @@
+package main
+
+// defaultIrrServer is the --irr-server default, broken out into a
+// constant so reconfigure can tell an explicit --irr-server apart from
+// a user simply not passing the flag.
+const defaultIrrServer = "rr.ntt.net"
+
+// CliFlags holds the command line flags parsed by go-flags.
+type CliFlags struct {
+	ConfigFile string `short:"c" long:"config" description:"Config file path" default:"/etc/pathvector.yml"`
+	Verbose    bool   `short:"v" long:"verbose" description:"Enable verbose logging"`
+
+	ShowVersion bool `long:"version" description:"Show version and exit"`
+	DryRun      bool `long:"dry-run" description:"Don't modify or create any files, just parse the config and print errors"`
+	NoConfigure bool `long:"no-configure" description:"Don't run birdc configure after generating config files"`
+	WorkerCount int  `long:"workers" description:"Number of peers to process concurrently" default:"4"`
+
+	RpkiDryRun bool `long:"rpki-dry-run" description:"Log RPKI-invalid prefixes without removing them from the filter"`
+
+	IrrServer      string   `long:"irr-server" description:"IRRd server to query for AS-SET expansion" default:"rr.ntt.net"` // keep in sync with defaultIrrServer
+	IrrSourceOrder []string `long:"irr-source-order" description:"Preferred IRR source order, e.g. RIPE,ARIN,APNIC,AFRINIC,LACNIC"`
+
+	RotateSecret string `long:"rotate-secret" description:"Force regeneration of the MD5/BFD secret for the named peer"`
+
+	StatuszListen string `long:"statusz-listen" description:"Address to serve /statusz, /healthz and /metrics on, e.g. :8080"`
+	Daemon        bool   `long:"daemon" description:"Stay resident and re-read the config on SIGHUP or file change"`
+}
+
+var cliFlags CliFlags
@@ -0,0 +1,104 @@
+package irr
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// cacheKeyPart replaces characters that aren't safe in a filename.
+var cacheKeyPart = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+// cacheDir is a var so tests can redirect it to a temp directory.
+var cacheDir = func() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), ".cache", "pathvector", "irr")
+	}
+	return filepath.Join(home, ".cache", "pathvector", "irr")
+}
+
+type cacheEntry struct {
+	Prefixes  []PrefixOrigin `json:"prefixes"`
+	FetchedAt time.Time      `json:"fetched_at"`
+}
+
+func cachePath(server, asSet string, family int) string {
+	key := fmt.Sprintf("%s_%s_v%d.json",
+		cacheKeyPart.ReplaceAllString(server, "_"),
+		cacheKeyPart.ReplaceAllString(asSet, "_"),
+		family)
+	return filepath.Join(cacheDir(), key)
+}
+
+// ResolveCached is ResolveWithOrigin backed by an on-disk cache, keyed by
+// (server, asSet, family), under ~/.cache/pathvector/irr/. A cache hit
+// younger than ttl skips the network call entirely.
+func ResolveCached(server, asSet string, family int, sourceOrder []string, ttl time.Duration) ([]PrefixOrigin, error) {
+	path := cachePath(server, asSet, family)
+
+	if cached, ok := readCache(path, ttl); ok {
+		return cached, nil
+	}
+
+	results, err := ResolveWithOrigin(server, asSet, family, sourceOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeCache(path, results); err != nil {
+		// A failed cache write shouldn't fail the resolution itself.
+		return results, nil
+	}
+	return results, nil
+}
+
+func readCache(path string, ttl time.Duration) ([]PrefixOrigin, bool) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+
+	if time.Since(entry.FetchedAt) > ttl {
+		return nil, false
+	}
+	return entry.Prefixes, true
+}
+
+// writeCache writes entry atomically: temp file in the same directory,
+// then rename.
+func writeCache(path string, prefixes []PrefixOrigin) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(cacheEntry{Prefixes: prefixes, FetchedAt: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	tmpFile, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmp := tmpFile.Name()
+	defer os.Remove(tmp) // no-op once the rename below succeeds
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,44 @@
+package irr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCacheRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	old := cacheDir
+	cacheDir = func() string { return dir }
+	defer func() { cacheDir = old }()
+
+	path := cachePath("rr.ntt.net", "AS-EXAMPLE", 4)
+	want := []PrefixOrigin{{Prefix: "203.0.113.0/24", Origin: 65001}}
+
+	if err := writeCache(path, want); err != nil {
+		t.Fatalf("writeCache: %v", err)
+	}
+
+	got, ok := readCache(path, time.Hour)
+	if !ok {
+		t.Fatal("expected cache hit")
+	}
+	if len(got) != 1 || got[0] != want[0] {
+		t.Errorf("readCache = %+v, want %+v", got, want)
+	}
+}
+
+func TestCacheExpires(t *testing.T) {
+	dir := t.TempDir()
+	old := cacheDir
+	cacheDir = func() string { return dir }
+	defer func() { cacheDir = old }()
+
+	path := cachePath("rr.ntt.net", "AS-EXAMPLE", 4)
+	if err := writeCache(path, []PrefixOrigin{{Prefix: "203.0.113.0/24", Origin: 65001}}); err != nil {
+		t.Fatalf("writeCache: %v", err)
+	}
+
+	if _, ok := readCache(path, -time.Second); ok {
+		t.Error("expected cache miss for already-expired TTL")
+	}
+}
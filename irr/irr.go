@@ -0,0 +1,195 @@
+// Package irr resolves AS-SETs to prefix lists by querying an IRRd
+// server's whois-style protocol on port 43.
+package irr
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dialTimeout is a var so tests can shorten it.
+var dialTimeout = 10 * time.Second
+
+// PrefixOrigin is a prefix paired with the ASN it was resolved from.
+type PrefixOrigin struct {
+	Prefix string
+	Origin uint32
+}
+
+// Resolve expands asSet into the flat, deduplicated, sorted list of
+// prefixes originated by its member ASNs in the given family (4 or 6).
+func Resolve(server, asSet string, family int, sourceOrder []string) ([]string, error) {
+	withOrigin, err := ResolveWithOrigin(server, asSet, family, sourceOrder)
+	if err != nil {
+		return nil, err
+	}
+	// withOrigin is sorted by prefix, so a prefix announced by several
+	// member ASNs shows up as adjacent entries.
+	prefixes := make([]string, 0, len(withOrigin))
+	for _, po := range withOrigin {
+		if n := len(prefixes); n > 0 && prefixes[n-1] == po.Prefix {
+			continue
+		}
+		prefixes = append(prefixes, po.Prefix)
+	}
+	return prefixes, nil
+}
+
+// ResolveWithOrigin is Resolve but keeps the originating ASN for each
+// prefix, as required to validate the result against RPKI. sourceOrder,
+// when non-empty, is sent as an IRRd "!s" command so e.g. a RIPE route
+// object is preferred over an ARIN one for the same prefix.
+func ResolveWithOrigin(server, asSet string, family int, sourceOrder []string) ([]PrefixOrigin, error) {
+	conn, err := net.DialTimeout("tcp", server+":43", dialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", server, err)
+	}
+	defer conn.Close()
+
+	return resolveOverConn(conn, asSet, family, sourceOrder)
+}
+
+// resolveOverConn is ResolveWithOrigin's wire-protocol logic, split out
+// so tests can drive it over a net.Pipe instead of a real TCP dial.
+func resolveOverConn(conn net.Conn, asSet string, family int, sourceOrder []string) ([]PrefixOrigin, error) {
+	r := bufio.NewReader(conn)
+
+	// Switch to persistent mode up front: without "!!" IRRd closes the
+	// connection after answering a single query, but we need one "!i"
+	// expansion followed by an "!g"/"!6" per member ASN. IRRd doesn't
+	// send a response to "!!" itself, so write it directly.
+	if _, err := conn.Write([]byte("!!\n")); err != nil {
+		return nil, fmt.Errorf("entering persistent mode: %w", err)
+	}
+
+	if len(sourceOrder) > 0 {
+		if _, err := query(conn, r, "!s"+strings.Join(sourceOrder, ",")); err != nil {
+			return nil, fmt.Errorf("setting source order: %w", err)
+		}
+	}
+
+	asns, err := query(conn, r, fmt.Sprintf("!i%s,1", asSet))
+	if err != nil {
+		return nil, fmt.Errorf("expand %s: %w", asSet, err)
+	}
+
+	seen := map[string]bool{}
+	var results []PrefixOrigin
+	cmd := "!g"
+	if family == 6 {
+		cmd = "!6"
+	}
+	for _, asn := range asns {
+		asn = strings.TrimPrefix(asn, "AS")
+		origin, err := strconv.ParseUint(asn, 10, 32)
+		if err != nil {
+			continue
+		}
+		prefixes, err := query(conn, r, cmd+"AS"+asn)
+		if err != nil {
+			return nil, fmt.Errorf("query prefixes for AS%s: %w", asn, err)
+		}
+		for _, p := range prefixes {
+			key := asn + "/" + p
+			if !seen[key] {
+				seen[key] = true
+				results = append(results, PrefixOrigin{Prefix: p, Origin: uint32(origin)})
+			}
+		}
+	}
+
+	if _, err := conn.Write([]byte("!q\n")); err != nil {
+		return nil, err
+	}
+
+	sortPrefixOrigins(results)
+	return results, nil
+}
+
+// queryTimeout bounds each read from the server; it's a var so tests can
+// shorten it.
+var queryTimeout = 30 * time.Second
+
+// query sends a single IRRd command and reads its length-prefixed
+// response, e.g. "A123\n<123 bytes including trailing newline>C\n".
+func query(conn net.Conn, r *bufio.Reader, cmd string) ([]string, error) {
+	if err := conn.SetDeadline(time.Now().Add(queryTimeout)); err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.Write([]byte(cmd + "\n")); err != nil {
+		return nil, err
+	}
+
+	status, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	status = strings.TrimSpace(status)
+
+	switch {
+	case strings.HasPrefix(status, "A"):
+		// Followed by exactly <n> bytes, which IRRd counts inclusive of
+		// the payload's own trailing newline, then the "C\n" terminator.
+		var n int
+		if _, err := fmt.Sscanf(status, "A%d", &n); err != nil {
+			return nil, fmt.Errorf("malformed length response %q", status)
+		}
+		buf := make([]byte, n)
+		if _, err := readFull(r, buf); err != nil {
+			return nil, err
+		}
+		if _, err := r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		return strings.Fields(string(buf)), nil
+	case strings.HasPrefix(status, "C"):
+		return nil, nil
+	case strings.HasPrefix(status, "D"):
+		return nil, nil
+	case strings.HasPrefix(status, "F"):
+		return nil, fmt.Errorf("irrd error: %s", status)
+	default:
+		return nil, fmt.Errorf("unexpected irrd response: %s", status)
+	}
+}
+
+func readFull(r *bufio.Reader, buf []byte) (int, error) {
+	total := 0
+	for total < len(buf) {
+		n, err := r.Read(buf[total:])
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// FormatBirdEntry renders a resolved prefix as a BIRD prefix-set literal
+// entry with bgpq4 -Ab compatible {min,max} length bounds: min is the
+// prefix's own length and max is maxLen, widened to cover the prefix
+// itself if maxLen is shorter than it.
+func FormatBirdEntry(prefix string, maxLen int) (string, error) {
+	_, network, err := net.ParseCIDR(prefix)
+	if err != nil {
+		return "", fmt.Errorf("parsing prefix %q: %w", prefix, err)
+	}
+	ones, _ := network.Mask.Size()
+	if maxLen < ones {
+		maxLen = ones
+	}
+	return fmt.Sprintf("%s{%d,%d}", prefix, ones, maxLen), nil
+}
+
+func sortPrefixOrigins(s []PrefixOrigin) {
+	for i := 1; i < len(s); i++ {
+		for j := i; j > 0 && s[j-1].Prefix > s[j].Prefix; j-- {
+			s[j-1], s[j] = s[j], s[j-1]
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package irr
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"testing"
+)
+
+// fakeIRRd serves canned IRRd frames over conn until the client sends
+// "!q", then closes its side. Each entry in responses maps a command to
+// the raw bytes to write back for it (status line + payload, already
+// correctly framed); handshake ("!!") expects no response.
+func fakeIRRd(t *testing.T, conn net.Conn, responses map[string]string) {
+	t.Helper()
+	go func() {
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := line[:len(line)-1]
+			if cmd == "!q" {
+				return
+			}
+			if cmd == "!!" {
+				continue // no response to the persistent-mode handshake
+			}
+			resp, ok := responses[cmd]
+			if !ok {
+				conn.Write([]byte("F unexpected query\n"))
+				continue
+			}
+			conn.Write([]byte(resp))
+		}
+	}()
+}
+
+func TestResolveWithOriginFullFlow(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+
+	fakeIRRd(t, server, map[string]string{
+		"!iAS-EXAMPLE,1": "A8\nAS65001\nC\n",
+		"!gAS65001":      "A15\n203.0.113.0/24\nC\n",
+	})
+
+	got, err := resolveOverConn(client, "AS-EXAMPLE", 4, nil)
+	if err != nil {
+		t.Fatalf("resolveOverConn: %v", err)
+	}
+	want := []PrefixOrigin{{Prefix: "203.0.113.0/24", Origin: 65001}}
+	if len(got) != 1 || got[0] != want[0] {
+		t.Errorf("resolveOverConn = %+v, want %+v", got, want)
+	}
+}
+
+func TestQueryFramingDoesNotOverread(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+
+	// The "A<n>" byte count is inclusive of the payload's own trailing
+	// newline, so after reading it the stream is already at "C\n" - a
+	// second ReadString here would consume the next query's response.
+	fakeIRRd(t, server, map[string]string{
+		"!gAS65001": "A15\n203.0.113.0/24\nC\n",
+		"!gAS65002": "A15\n203.0.114.0/24\nC\n",
+	})
+
+	r := bufio.NewReader(client)
+	first, err := query(client, r, "!gAS65001")
+	if err != nil {
+		t.Fatalf("first query: %v", err)
+	}
+	if fmt.Sprint(first) != "[203.0.113.0/24]" {
+		t.Fatalf("first query = %v, want [203.0.113.0/24]", first)
+	}
+
+	second, err := query(client, r, "!gAS65002")
+	if err != nil {
+		t.Fatalf("second query: %v", err)
+	}
+	if fmt.Sprint(second) != "[203.0.114.0/24]" {
+		t.Fatalf("second query = %v, want [203.0.114.0/24] (got stuck reading previous frame's terminator)", second)
+	}
+}
+
+func TestFormatBirdEntry(t *testing.T) {
+	cases := []struct {
+		name    string
+		prefix  string
+		maxLen  int
+		want    string
+		wantErr bool
+	}{
+		{"widens to configured max", "203.0.113.0/24", 32, "203.0.113.0/24{24,32}", false},
+		{"max shorter than own length is widened to own length", "203.0.113.0/24", 16, "203.0.113.0/24{24,24}", false},
+		{"invalid prefix errors", "not-a-prefix", 24, "", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := FormatBirdEntry(tc.prefix, tc.maxLen)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got entry %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("FormatBirdEntry(%q, %d) = %q, want %q", tc.prefix, tc.maxLen, got, tc.want)
+			}
+		})
+	}
+}
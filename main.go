@@ -1,30 +1,57 @@
 package main
 
 import (
+	"context"
 	"embed"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"os/signal"
 	"path"
 	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 	"unicode"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/jessevdk/go-flags"
 	log "github.com/sirupsen/logrus"
+
+	"github.com/natesales/wireframe/config"
+	"github.com/natesales/wireframe/processors"
+	"github.com/natesales/wireframe/statusz"
 )
 
 var version = "devel" // set by the build process
 
+// peer and globalConfig alias the shared config package's types so the
+// rest of this package can keep referring to them by their historical,
+// unqualified names.
+type peer = config.Peer
+type globalConfig = config.Config
+
 // Embedded filesystem
 
 //go:embed templates/*
 var embedFs embed.FS
 
+// currentConfig is the most recently applied config, guarded by
+// currentConfigMu. It backs the statusz snapshot provider so /statusz
+// and /metrics stay live across --daemon reconfigure cycles.
+var (
+	currentConfigMu sync.RWMutex
+	currentConfig   *globalConfig
+	lastReconfigure string
+	lastReconfigErr string
+)
+
 // printPeerInfo prints a peer's configuration to the log
 func printPeerInfo(peerName string, peerData *peer) {
 	// Fields to exclude from print output
-	excludedFields := []string{"PrefixSet4", "PrefixSet6", "Name", "SessionGlobal", "PreImport", "PreExport", "PreImportFinal", "PreExportFinal", "QueryTime"}
+	excludedFields := []string{"PrefixSet4", "PrefixSet6", "Candidates4", "Candidates6", "Name", "SessionGlobal", "PreImport", "PreExport", "PreImportFinal", "PreExportFinal", "QueryTime", "MD5Password", "BfdAuthKey"}
 	s := reflect.ValueOf(peerData).Elem()
 	typeOf := s.Type()
 	for i := 0; i < s.NumField(); i++ {
@@ -59,23 +86,112 @@ func main() {
 	log.Infof("Starting  %s", version)
 
 	// Load templates from embedded filesystem
-	err = loadTemplates(embedFs)
-	if err != nil {
+	if err := loadTemplates(embedFs); err != nil {
 		log.Fatal(err)
 	}
-
 	log.Debug("Finished loading templates")
 
-	// Load the config file from config file
+	if cliFlags.StatuszListen != "" {
+		srv := statusz.NewServer(statuszSnapshot)
+		go func() {
+			if err := srv.ListenAndServe(cliFlags.StatuszListen); err != nil {
+				log.Errorf("statusz server on %s: %v", cliFlags.StatuszListen, err)
+			}
+		}()
+		log.Infof("statusz listening on %s", cliFlags.StatuszListen)
+	}
+
+	if cliFlags.Daemon {
+		runDaemon()
+		return
+	}
+
+	if err := reconfigure(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// runDaemon reconfigures once up front, then stays resident and
+// re-reads the config on SIGHUP or whenever the config file changes on
+// disk, until the process is killed.
+func runDaemon() {
+	if err := reconfigure(); err != nil {
+		log.Errorf("initial reconfigure failed: %v", err)
+	}
+
+	currentConfigMu.RLock()
+	rpkiCfg := currentConfig
+	currentConfigMu.RUnlock()
+	if rpkiCfg != nil {
+		rpkiStop := make(chan struct{})
+		defer close(rpkiStop)
+		go runRpkiRefreshLoop(rpkiCfg, rpkiStop)
+	}
+
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Fatalf("daemon: creating file watcher: %v", err)
+	}
+	defer watcher.Close()
+	if err := watcher.Add(filepath.Dir(cliFlags.ConfigFile)); err != nil {
+		log.Fatalf("daemon: watching %s: %v", cliFlags.ConfigFile, err)
+	}
+
+	log.Info("daemon mode: watching for SIGHUP and config file changes")
+	for {
+		select {
+		case <-sighup:
+			log.Info("received SIGHUP, reconfiguring")
+			if err := reconfigure(); err != nil {
+				log.Errorf("reconfigure failed: %v", err)
+			}
+
+		case event := <-watcher.Events:
+			if event.Name != cliFlags.ConfigFile || event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			log.Infof("%s changed, reconfiguring", cliFlags.ConfigFile)
+			if err := reconfigure(); err != nil {
+				log.Errorf("reconfigure failed: %v", err)
+			}
+
+		case err := <-watcher.Errors:
+			log.Errorf("daemon: file watcher error: %v", err)
+		}
+	}
+}
+
+// reconfigure loads the config file, runs the peer-enrichment pipeline,
+// and (re)writes every BIRD config file and reconfigures BIRD itself.
+// It's the entire unit of work --daemon re-runs on SIGHUP/file change.
+func reconfigure() error {
 	log.Debugf("Loading config from %s", cliFlags.ConfigFile)
 	configFile, err := ioutil.ReadFile(cliFlags.ConfigFile)
 	if err != nil {
-		log.Fatal("reading config file: " + err.Error())
+		recordReconfigure(err)
+		return err
 	}
 
-	globalConfig, err := loadConfig(configFile)
+	globalConfig, err := config.Load(configFile)
 	if err != nil {
-		log.Fatal(err)
+		recordReconfigure(err)
+		return err
+	}
+	globalConfig.ConfigPath = cliFlags.ConfigFile
+	// Only let --irr-server override a config-file irr-server when it
+	// was actually passed; otherwise its own "rr.ntt.net" default would
+	// silently clobber a value set in YAML.
+	if cliFlags.IrrServer != defaultIrrServer || globalConfig.IrrServer == "" {
+		globalConfig.IrrServer = cliFlags.IrrServer
+	}
+	if len(cliFlags.IrrSourceOrder) > 0 {
+		globalConfig.IrrSourceOrder = cliFlags.IrrSourceOrder
+	}
+	if info, err := os.Stat(cliFlags.ConfigFile); err == nil {
+		globalConfig.ConfigModTime = info.ModTime()
 	}
 
 	if !cliFlags.DryRun {
@@ -83,141 +199,102 @@ func main() {
 		log.Debug("Creating global config")
 		globalFile, err := os.Create(path.Join(globalConfig.BirdDirectory, "bird.conf"))
 		if err != nil {
-			log.Fatalf("Create global BIRD output file: %v", err)
+			recordReconfigure(err)
+			return err
 		}
 		log.Debug("Finished creating global config file")
 
 		// Render the global template and write to disk
 		log.Debug("Writing global config file")
-		err = globalTemplate.ExecuteTemplate(globalFile, "global.tmpl", globalConfig)
-		if err != nil {
-			log.Fatalf("Execute global template: %v", err)
+		if err := globalTemplate.ExecuteTemplate(globalFile, "global.tmpl", globalConfig); err != nil {
+			recordReconfigure(err)
+			return err
 		}
 		log.Debug("Finished writing global config file")
 
 		// Remove old peer-specific configs
 		files, err := filepath.Glob(path.Join(globalConfig.BirdSocket, "AS*.conf"))
 		if err != nil {
-			log.Fatal(err)
+			recordReconfigure(err)
+			return err
 		}
 		for _, f := range files {
 			if err := os.Remove(f); err != nil {
-				log.Fatalf("Removing old config files: %v", err)
+				recordReconfigure(err)
+				return err
 			}
 		}
 	} else {
 		log.Info("Dry run is enabled, skipped writing global config and removing old peer configs")
 	}
 
-	// Iterate over peers
+	// Set sanitized protocol names up front so every processor and the
+	// render step can rely on ProtocolName being populated.
 	for peerName, peerData := range globalConfig.Peers {
-		// Set sanitized peer name
+		peerData.Name = peerName
 		if unicode.IsDigit(rune(peerName[0])) {
 			// Add peer prefix if the first character of peerName is a number
 			peerData.ProtocolName = "PEER_" + sanitize(peerName)
 		} else {
 			peerData.ProtocolName = sanitize(peerName)
 		}
-
 		log.Infof("Checking config for %s AS%d", peerName, peerData.Asn)
+	}
+
+	// Run the peer-enrichment pipeline (PeeringDB, IRR, RPKI, secretgen,
+	// render) with per-peer error isolation.
+	summary, err := processors.Run(context.Background(), globalConfig.Peers, globalConfig, cliFlags.WorkerCount)
+	if err != nil {
+		recordReconfigure(err)
+		return err
+	}
+	log.Infof("Pipeline finished: %d peers processed, %d skipped", summary.PeersProcessed, summary.PeersSkipped)
+	for name, stats := range summary.PerProcessor {
+		log.Infof("[%s] %d succeeded, %d failed", name, stats.Succeeded, stats.Failed)
+	}
 
-		//	if !peerData.NoPeeringDB {
-		//		// Only query PeeringDB and IRRDB for peers and downstreams, TODO: This should validate upstreams too
-		//		peerData.QueryTime = time.Now().Format(time.RFC1123)
-		//		peeringDbData := getPeeringDbData(peerData.Asn)
-		//
-		//		if peerData.ImportLimit4 == 0 {
-		//			peerData.ImportLimit4 = peeringDbData.MaxPfx4
-		//			log.Infof("[%s] has no IPv4 import limit configured. Setting to %d from PeeringDB", peerName, peeringDbData.MaxPfx4)
-		//		}
-		//
-		//		if peerData.ImportLimit6 == 0 {
-		//			peerData.ImportLimit6 = peeringDbData.MaxPfx6
-		//			log.Infof("[%s] has no IPv6 import limit configured. Setting to %d from PeeringDB", peerName, peeringDbData.MaxPfx6)
-		//		}
-		//
-		//		// Only set AS-SET from PeeringDB if it isn't configure manually
-		//		if peerData.AsSet == "" {
-		//			// If the as-set has a space in it, split and pick the first element
-		//			if strings.Contains(peeringDbData.AsSet, " ") {
-		//				peeringDbData.AsSet = strings.Split(peeringDbData.AsSet, " ")[0]
-		//				log.Warnf("[%s] has a space in their PeeringDB as-set field. Selecting first element %s", peerName, peeringDbData.AsSet)
-		//			}
-		//
-		//			// Trim IRRDB prefix
-		//			if strings.Contains(peeringDbData.AsSet, "::") {
-		//				peerData.AsSet = strings.Split(peeringDbData.AsSet, "::")[1]
-		//				log.Warnf("[%s] has a IRRDB prefix in their PeeringDB as-set field. Using %s", peerName, peerData.AsSet)
-		//			} else {
-		//				peerData.AsSet = peeringDbData.AsSet
-		//			}
-		//
-		//			if peeringDbData.AsSet == "" {
-		//				log.Warnf("[%s] has no as-set in PeeringDB, falling back to their ASN (%d)", peerName, peerData.Asn)
-		//				peerData.AsSet = fmt.Sprintf("AS%d", peerData.Asn)
-		//			} else {
-		//				log.Infof("[%s] has no manual AS-SET defined. Setting to %s from PeeringDB\n", peerName, peeringDbData.AsSet)
-		//			}
-		//		} else {
-		//			log.Infof("[%s] has manual AS-SET: %s", peerName, peerData.AsSet)
-		//		}
-		//
-		//		//peerData.PrefixSet4 = getPrefixFilter(peerData.AsSet, 4, globalConfig.IrrDb)
-		//		//peerData.PrefixSet6 = getPrefixFilter(peerData.AsSet, 6, globalConfig.IrrDb)
-		//
-		//		// Update the "latest operation" timestamp
-		//		//peerData.QueryTime = time.Now().Format(time.RFC1123)
-		//	}
-		//
-		//	// If as-set is empty and the peer type requires it
-		//	if peerData.AsSet == "" && (peerData.Type == "peer" || peerData.Type == "downstream") {
-		//		log.Fatalf("[%s] has no AS-SET defined and filtering profile requires it.", peerName)
-		//	}
-		//
-		//	// Print peer info
-		//	printPeerInfo(peerName, peerData)
-		//
-		//	if !cliFlags.DryRun {
-		//		// Create the peer specific file
-		//		peerSpecificFile, err := os.Create(path.Join(globalConfig.BirdDirectory, "AS"+strconv.Itoa(int(peerData.Asn))+"_"+normalize(peerName)+".conf"))
-		//		if err != nil {
-		//			log.Fatalf("Create peer specific output file: %v", err)
-		//		}
-		//
-		//		// Render the template and write to disk
-		//		log.Infof("[%s] Writing config", peerName)
-		//		err = peerTemplate.ExecuteTemplate(peerSpecificFile, "peer.tmpl", &Wrapper{Peer: *peerData, Config: *globalConfig})
-		//		if err != nil {
-		//			log.Fatalf("Execute template: %v", err)
-		//		}
-		//
-		//		log.Infof("[%s] Wrote config", peerName)
-		//	} else {
-		//		log.Infof("Dry run is enabled, skipped writing peer config(s)")
-		//	}
-		//}
-		//
-		//if !cliFlags.DryRun {
-		//	// Write VRRP config
-		//	writeVrrpConfig(globalConfig)
-		//
-		//	if globalConfig.BirdSocket != "" {
-		//		writeUiFile(globalConfig)
-		//	} else {
-		//		log.Infof("--ui-file is not defined, not creating a UI file")
-		//	}
-		//
-		//	if !cliFlags.NoConfigure {
-		//		log.Infoln("Reconfiguring BIRD")
-		//		if err = runBirdCommand("configure", globalConfig.BirdSocket); err != nil {
-		//			log.Fatal(err)
-		//		}
-		//	} else {
-		//		log.Infoln("Option --no-configure is set, NOT reconfiguring bird")
-		//	}
-		//
-		//	// Configure interfaces
-		//	configureInterfaces(globalConfig)
-		//}
+	for peerName, peerData := range globalConfig.Peers {
+		printPeerInfo(peerName, peerData)
+	}
+
+	if !cliFlags.DryRun {
+		// Write VRRP config
+		writeVrrpConfig(globalConfig)
+
+		if globalConfig.BirdSocket != "" {
+			writeUiFile(globalConfig)
+		} else {
+			log.Infof("--ui-file is not defined, not creating a UI file")
+		}
+
+		if !cliFlags.NoConfigure {
+			log.Infoln("Reconfiguring BIRD")
+			if err := runBirdCommand("configure", globalConfig.BirdSocket); err != nil {
+				recordReconfigure(err)
+				return err
+			}
+		} else {
+			log.Infoln("Option --no-configure is set, NOT reconfiguring bird")
+		}
+
+		// Configure interfaces
+		configureInterfaces(globalConfig)
+	}
+
+	currentConfigMu.Lock()
+	currentConfig = globalConfig
+	currentConfigMu.Unlock()
+	recordReconfigure(nil)
+	return nil
+}
+
+func recordReconfigure(err error) {
+	currentConfigMu.Lock()
+	defer currentConfigMu.Unlock()
+	lastReconfigure = fmt.Sprintf("success at %s", time.Now().Format(time.RFC1123))
+	lastReconfigErr = ""
+	if err != nil {
+		lastReconfigure = fmt.Sprintf("failed at %s", time.Now().Format(time.RFC1123))
+		lastReconfigErr = err.Error()
 	}
 }
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/natesales/wireframe/config"
+	"github.com/natesales/wireframe/irr"
+	"github.com/natesales/wireframe/processors"
+	"github.com/natesales/wireframe/statusz"
+)
+
+// irrProcessor resolves a peer's AS-SET into concrete prefix lists via
+// IRR. It depends on peeringdb so that an AS-SET discovered there is
+// already populated by the time this runs.
+type irrProcessor struct{}
+
+func (irrProcessor) Name() string        { return "irr" }
+func (irrProcessor) DependsOn() []string { return []string{"peeringdb"} }
+
+func (irrProcessor) Process(ctx context.Context, peer *config.Peer, cfg *config.Config) error {
+	if peer.Type != "peer" && peer.Type != "downstream" {
+		return nil
+	}
+	if peer.AsSet == "" {
+		return fmt.Errorf("peer has no AS-SET defined and filtering profile requires it")
+	}
+
+	start := time.Now()
+	candidates4, err := irr.ResolveCached(cfg.IrrServer, peer.AsSet, 4, cfg.IrrSourceOrder, cfg.IrrCacheTtl)
+	if err != nil {
+		return fmt.Errorf("resolving IPv4 prefixes for %s: %w", peer.AsSet, err)
+	}
+	candidates6, err := irr.ResolveCached(cfg.IrrServer, peer.AsSet, 6, cfg.IrrSourceOrder, cfg.IrrCacheTtl)
+	if err != nil {
+		return fmt.Errorf("resolving IPv6 prefixes for %s: %w", peer.AsSet, err)
+	}
+	statusz.RecordIrrQueryDuration(time.Since(start))
+	statusz.SetCacheFreshness("irr", time.Now())
+
+	if len(candidates4) == 0 && len(candidates6) == 0 {
+		return fmt.Errorf("AS-SET %s resolved to an empty prefix list", peer.AsSet)
+	}
+
+	peer.Candidates4 = toConfigPrefixOrigins(candidates4)
+	peer.Candidates6 = toConfigPrefixOrigins(candidates6)
+
+	// Populated provisionally here, as bgpq4-style prefix-set literals,
+	// so peers are filterable even when rpki is disabled (empty VRP
+	// cache); the rpki processor narrows these down when it's enabled.
+	set4, err := formatBirdEntries(prefixStrings(candidates4), cfg.MaxPrefixLen4)
+	if err != nil {
+		return err
+	}
+	set6, err := formatBirdEntries(prefixStrings(candidates6), cfg.MaxPrefixLen6)
+	if err != nil {
+		return err
+	}
+	peer.PrefixSet4 = set4
+	peer.PrefixSet6 = set6
+	return nil
+}
+
+// formatBirdEntries renders every prefix as a bgpq4 -Ab compatible BIRD
+// prefix-set literal entry, e.g. "203.0.113.0/24{24,24}".
+func formatBirdEntries(prefixes []string, maxLen int) ([]string, error) {
+	out := make([]string, len(prefixes))
+	for i, p := range prefixes {
+		entry, err := irr.FormatBirdEntry(p, maxLen)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = entry
+	}
+	return out, nil
+}
+
+func toConfigPrefixOrigins(in []irr.PrefixOrigin) []config.PrefixOrigin {
+	out := make([]config.PrefixOrigin, len(in))
+	for i, po := range in {
+		out[i] = config.PrefixOrigin{Prefix: po.Prefix, Origin: po.Origin}
+	}
+	return out
+}
+
+func prefixStrings(in []irr.PrefixOrigin) []string {
+	out := make([]string, len(in))
+	for i, po := range in {
+		out[i] = po.Prefix
+	}
+	return out
+}
+
+func init() {
+	processors.Register(irrProcessor{})
+}
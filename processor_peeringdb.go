@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/natesales/wireframe/config"
+	"github.com/natesales/wireframe/processors"
+	log "github.com/sirupsen/logrus"
+)
+
+// peeringDbData is the subset of PeeringDB's net object we care about.
+type peeringDbData struct {
+	AsSet   string `json:"irr_as_set"`
+	MaxPfx4 int    `json:"info_prefixes4"`
+	MaxPfx6 int    `json:"info_prefixes6"`
+}
+
+// fetchPeeringDb is a var so tests can substitute a fake lookup.
+var fetchPeeringDb = func(asn uint32) (peeringDbData, error) {
+	resp, err := http.Get(fmt.Sprintf("https://www.peeringdb.com/api/net?asn=%d", asn))
+	if err != nil {
+		return peeringDbData{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return peeringDbData{}, err
+	}
+
+	var parsed struct {
+		Data []peeringDbData `json:"data"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return peeringDbData{}, err
+	}
+	if len(parsed.Data) == 0 {
+		return peeringDbData{}, fmt.Errorf("no PeeringDB record for AS%d", asn)
+	}
+	return parsed.Data[0], nil
+}
+
+// peeringDbProcessor fills in a peer's AS-SET and import limits from
+// PeeringDB when they aren't set manually.
+type peeringDbProcessor struct{}
+
+func (peeringDbProcessor) Name() string        { return "peeringdb" }
+func (peeringDbProcessor) DependsOn() []string { return nil }
+
+func (peeringDbProcessor) Process(ctx context.Context, peer *config.Peer, cfg *config.Config) error {
+	if peer.NoPeeringDB {
+		log.Debug("peer has no-peeringdb set, skipping")
+		return nil
+	}
+
+	data, err := fetchPeeringDb(peer.Asn)
+	if err != nil {
+		peer.LastStatus = "PeeringDB-missing"
+		return err
+	}
+
+	if peer.ImportLimit4 == 0 {
+		peer.ImportLimit4 = data.MaxPfx4
+		log.Infof("has no IPv4 import limit configured, setting to %d from PeeringDB", data.MaxPfx4)
+	}
+	if peer.ImportLimit6 == 0 {
+		peer.ImportLimit6 = data.MaxPfx6
+		log.Infof("has no IPv6 import limit configured, setting to %d from PeeringDB", data.MaxPfx6)
+	}
+
+	if peer.AsSet == "" {
+		asSet := data.AsSet
+		if strings.Contains(asSet, " ") {
+			asSet = strings.Split(asSet, " ")[0]
+			log.Warnf("PeeringDB as-set field has a space, selecting first element %s", asSet)
+		}
+		if strings.Contains(asSet, "::") {
+			asSet = strings.Split(asSet, "::")[1]
+			log.Warnf("PeeringDB as-set field has an IRRDB prefix, using %s", asSet)
+		}
+		if asSet == "" {
+			asSet = fmt.Sprintf("AS%d", peer.Asn)
+			log.Warnf("no as-set in PeeringDB, falling back to %s", asSet)
+		}
+		peer.AsSet = asSet
+	}
+
+	return nil
+}
+
+func init() {
+	processors.Register(peeringDbProcessor{})
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/natesales/wireframe/config"
+	"github.com/natesales/wireframe/processors"
+	log "github.com/sirupsen/logrus"
+)
+
+// renderProcessor writes the peer-specific BIRD config file. It runs
+// last, once every other processor has finished filling in the peer.
+type renderProcessor struct{}
+
+func (renderProcessor) Name() string { return "render" }
+func (renderProcessor) DependsOn() []string {
+	return []string{"irr", "rpki", "secretgen"}
+}
+
+func (renderProcessor) Process(ctx context.Context, peer *config.Peer, cfg *config.Config) error {
+	defer func() { peer.LastProcessed = time.Now() }()
+
+	if cliFlags.DryRun {
+		log.Infof("dry run is enabled, skipped writing peer config for %s", peer.ProtocolName)
+		return nil
+	}
+
+	peerSpecificFile, err := os.Create(path.Join(cfg.BirdDirectory, "AS"+strconv.Itoa(int(peer.Asn))+"_"+peer.ProtocolName+".conf"))
+	if err != nil {
+		return err
+	}
+	defer peerSpecificFile.Close()
+
+	return peerTemplate.ExecuteTemplate(peerSpecificFile, "peer.tmpl", &Wrapper{Peer: *peer, Config: *cfg})
+}
+
+func init() {
+	processors.Register(renderProcessor{})
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/natesales/wireframe/config"
+	"github.com/natesales/wireframe/irr"
+	"github.com/natesales/wireframe/processors"
+	"github.com/natesales/wireframe/rpki"
+	"github.com/natesales/wireframe/statusz"
+	log "github.com/sirupsen/logrus"
+)
+
+// rpkiCache is shared across peers within a single run: VRPs are global,
+// not per-peer, so there's no reason to fetch them once per peer.
+var rpkiCache = rpki.NewCache()
+
+// rpkiRefreshOnce ensures the cache is populated at most once per run,
+// regardless of how many peers the worker pool processes concurrently.
+var rpkiRefreshOnce sync.Once
+
+// rpkiClient is the RTR client backing both the initial refresh and the
+// background refresh loop. It's a single persistent instance because
+// RFC 8210 Serial Queries carry the session ID and serial number the
+// *previous* call on this connection's cache observed - a fresh Client
+// per call would never have incremental state to query from.
+var rpkiClient *rpki.Client
+
+// rpkiRefresh is a var so tests can substitute a fake loader.
+var rpkiRefresh = func(cfg *config.Config) {
+	switch {
+	case cfg.RtrServer != "":
+		rpkiClient = rpki.NewClient(cfg.RtrServer)
+		if _, err := rpkiClient.Refresh(rpkiCache); err != nil {
+			log.Errorf("rpki: refreshing VRP cache from %s: %v", cfg.RtrServer, err)
+			return
+		}
+		statusz.SetCacheFreshness("rpki", time.Now())
+	case cfg.RpkiRoaUrl != "":
+		cache, err := rpki.LoadJSON(cfg.RpkiRoaUrl)
+		if err != nil {
+			log.Errorf("rpki: loading ROA dump from %s: %v", cfg.RpkiRoaUrl, err)
+			return
+		}
+		rpkiCache = cache
+		statusz.SetCacheFreshness("rpki", time.Now())
+	default:
+		log.Debug("rpki: no RtrServer or RpkiRoaUrl configured, RPKI validation disabled")
+	}
+}
+
+// runRpkiRefreshLoop keeps rpkiCache fresh in the background using the
+// RTR server's advertised refresh/retry/expire timers (RFC 8210 section
+// 5.8): a Serial Query every Refresh interval, a full Reset Query once
+// Expire has elapsed since the last one (or immediately if the server
+// responds to a Serial Query with a Cache Reset), and a Retry-interval
+// backoff on error. Only --daemon mode lives long enough for any of
+// this to fire, so main starts it from runDaemon, not from reconfigure.
+// Blocks until stop is closed.
+func runRpkiRefreshLoop(cfg *config.Config, stop <-chan struct{}) {
+	if cfg.RtrServer == "" {
+		return // JSON ROA dumps have no incremental-update protocol to poll
+	}
+
+	rpkiRefreshOnce.Do(func() { rpkiRefresh(cfg) })
+	if rpkiClient == nil {
+		return // initial refresh never got far enough to have a client
+	}
+
+	timers := rpki.DefaultTimers
+	lastFull := time.Now()
+
+	for {
+		select {
+		case <-time.After(timers.Refresh):
+		case <-stop:
+			return
+		}
+
+		full := time.Since(lastFull) > timers.Expire
+		var t rpki.Timers
+		var err error
+		if full {
+			t, err = rpkiClient.Refresh(rpkiCache)
+		} else {
+			t, err = rpkiClient.SerialRefresh(rpkiCache)
+			if err != nil {
+				// Most likely the server sent a Cache Reset because our
+				// serial fell out of its retention window; fall back to
+				// a full Reset Query right away instead of waiting out
+				// the Retry interval first.
+				t, err = rpkiClient.Refresh(rpkiCache)
+				full = true
+			}
+		}
+
+		if err != nil {
+			log.Errorf("rpki: background refresh failed, retrying in %s: %v", timers.Retry, err)
+			select {
+			case <-time.After(timers.Retry):
+			case <-stop:
+				return
+			}
+			continue
+		}
+
+		timers = t
+		if full {
+			lastFull = time.Now()
+		}
+		statusz.SetCacheFreshness("rpki", time.Now())
+	}
+}
+
+// rpkiProcessor drops candidate prefixes that fail RFC 6811 RPKI origin
+// validation. It runs after irr, which is what populates the candidate
+// (prefix, origin) pairs this validates.
+type rpkiProcessor struct{}
+
+func (rpkiProcessor) Name() string        { return "rpki" }
+func (rpkiProcessor) DependsOn() []string { return []string{"irr"} }
+
+func (rpkiProcessor) Process(ctx context.Context, peer *config.Peer, cfg *config.Config) error {
+	rpkiRefreshOnce.Do(func() { rpkiRefresh(cfg) })
+
+	if rpkiCache.Size() == 0 {
+		// Nothing loaded: leave the irr-populated PrefixSet4/6 as-is.
+		return nil
+	}
+
+	set4, invalid4, err := filterValid(peer.Candidates4, cfg.MaxPrefixLen4)
+	if err != nil {
+		return err
+	}
+	set6, invalid6, err := filterValid(peer.Candidates6, cfg.MaxPrefixLen6)
+	if err != nil {
+		return err
+	}
+	peer.PrefixSet4 = set4
+	peer.PrefixSet6 = set6
+	peer.RpkiInvalidCount = invalid4 + invalid6
+
+	if peer.RpkiInvalidCount > 0 {
+		peer.LastStatus = "RPKI-invalid-" + strconv.Itoa(peer.RpkiInvalidCount)
+	}
+	return nil
+}
+
+// filterValid classifies every candidate, drops the ones that fail RPKI
+// origin validation (unless --rpki-dry-run is set), and renders the
+// survivors as bgpq4-style BIRD prefix-set literal entries.
+func filterValid(candidates []config.PrefixOrigin, maxLen int) ([]string, int, error) {
+	var kept []string
+	invalid := 0
+
+	for _, c := range candidates {
+		_, network, err := net.ParseCIDR(c.Prefix)
+		if err != nil {
+			log.Warnf("rpki: skipping unparseable prefix %q", c.Prefix)
+			continue
+		}
+
+		status := rpkiCache.Classify(network, c.Origin)
+		if status == rpki.Invalid {
+			invalid++
+			if !cliFlags.RpkiDryRun {
+				log.Warnf("dropping RPKI-invalid prefix %s (AS%d)", c.Prefix, c.Origin)
+				continue
+			}
+			log.Infof("rpki-dry-run: %s (AS%d) is RPKI-invalid, keeping in filter", c.Prefix, c.Origin)
+		}
+
+		entry, err := irr.FormatBirdEntry(c.Prefix, maxLen)
+		if err != nil {
+			return nil, invalid, err
+		}
+		kept = append(kept, entry)
+	}
+
+	return kept, invalid, nil
+}
+
+func init() {
+	processors.Register(rpkiProcessor{})
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+
+	"github.com/natesales/wireframe/config"
+	"github.com/natesales/wireframe/processors"
+	"github.com/natesales/wireframe/secretgen"
+	log "github.com/sirupsen/logrus"
+)
+
+// secretAuto is the sentinel value that tells secretgen to generate and
+// persist a new session secret for a field.
+const secretAuto = "AUTO"
+
+// secretState is the config-directory-local secrets.yaml, loaded once
+// per run regardless of how many peers the worker pool touches.
+var (
+	secretState     *secretgen.State
+	secretStateOnce sync.Once
+)
+
+func loadSecretState(cfg *config.Config) *secretgen.State {
+	secretStateOnce.Do(func() {
+		path := filepath.Join(filepath.Dir(cfg.ConfigPath), "secrets.yaml")
+		st, err := secretgen.LoadState(path)
+		if err != nil {
+			log.Errorf("secretgen: loading %s: %v, starting with an empty state", path, err)
+			st = secretgen.NewState(path)
+		}
+		secretState = st
+	})
+	return secretState
+}
+
+// secretgenProcessor replaces AUTO-valued MD5Password/BfdAuthKey fields
+// with generated secrets, rehydrating previously-generated values from
+// <config-dir>/secrets.yaml so the same session key survives config
+// regenerations. --rotate-secret <peerName> forces fresh material for
+// that peer's AUTO fields.
+type secretgenProcessor struct{}
+
+func (secretgenProcessor) Name() string        { return "secretgen" }
+func (secretgenProcessor) DependsOn() []string { return nil }
+
+func (secretgenProcessor) Process(ctx context.Context, peer *config.Peer, cfg *config.Config) error {
+	state := loadSecretState(cfg)
+
+	if err := ensureSecret(state, peer, "MD5Password", &peer.MD5Password); err != nil {
+		return err
+	}
+	if err := ensureSecret(state, peer, "BfdAuthKey", &peer.BfdAuthKey); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ensureSecret fills in *field if it's AUTO, reusing the persisted
+// secret unless a rotation was requested for this peer.
+func ensureSecret(state *secretgen.State, peer *config.Peer, fieldName string, field *string) error {
+	if *field != secretAuto {
+		return nil
+	}
+
+	rotate := cliFlags.RotateSecret == peer.Name
+	if !rotate {
+		if existing, ok := state.Get(peer.Name, fieldName); ok {
+			*field = existing
+			return nil
+		}
+	}
+
+	secret, err := secretgen.Generate(secretgen.DefaultAlphabet, secretgen.DefaultLength)
+	if err != nil {
+		return err
+	}
+	*field = secret
+
+	if rotate {
+		log.Warnf("[%s] rotated %s", peer.Name, fieldName)
+	} else {
+		log.Warnf("[%s] generated a new %s, it will be reused on future runs", peer.Name, fieldName)
+	}
+	return state.Set(peer.Name, fieldName, secret)
+}
+
+func init() {
+	processors.Register(secretgenProcessor{})
+}
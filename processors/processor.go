@@ -0,0 +1,172 @@
+// Package processors implements the pluggable peer-enrichment pipeline.
+// Each Processor takes a single peer and mutates it in place; the Run
+// driver fans the registered processors out across peers with a bounded
+// worker pool, isolating failures to the peer that caused them.
+package processors
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/natesales/wireframe/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// Processor is a single step of the peer-enrichment pipeline, e.g.
+// resolving an AS-SET via IRR or filtering prefixes against RPKI.
+type Processor interface {
+	// Name is the processor's unique, stable identifier used for
+	// dependency declarations, logging and the run summary.
+	Name() string
+
+	// DependsOn lists the names of processors that must have run
+	// (successfully) on a peer before this one does.
+	DependsOn() []string
+
+	// Process mutates peer in place. Returning an error marks the peer
+	// as skipped for the remainder of the pipeline; it does not abort
+	// the run for other peers.
+	Process(ctx context.Context, peer *config.Peer, cfg *config.Config) error
+}
+
+var registry = map[string]Processor{}
+
+// Register adds a processor to the built-in registry. It is expected to
+// be called from init() in each processor's file.
+func Register(p Processor) {
+	registry[p.Name()] = p
+}
+
+// ordered topologically sorts the registered processors by DependsOn so
+// that Run always executes them in a valid dependency order. The DFS is
+// seeded in sorted-name order, not registry map iteration order, so
+// independent processors always come out in the same relative order
+// from run to run.
+func ordered() ([]Processor, error) {
+	var sorted []Processor
+	visited := map[string]int{} // 0 = unvisited, 1 = visiting, 2 = done
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch visited[name] {
+		case 2:
+			return nil
+		case 1:
+			return fmt.Errorf("processors: dependency cycle at %q", name)
+		}
+		p, ok := registry[name]
+		if !ok {
+			return fmt.Errorf("processors: unknown dependency %q", name)
+		}
+		visited[name] = 1
+		for _, dep := range p.DependsOn() {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		visited[name] = 2
+		sorted = append(sorted, p)
+		return nil
+	}
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+// ProcessorStats tallies how many peers a processor succeeded or failed
+// on during a single Run.
+type ProcessorStats struct {
+	Succeeded int
+	Failed    int
+}
+
+// Summary is the final per-processor and per-peer report from Run.
+type Summary struct {
+	PeersProcessed int
+	PeersSkipped   int
+	PerProcessor   map[string]*ProcessorStats
+}
+
+// Run executes every registered processor, in dependency order, against
+// every peer in peers. Peers are processed concurrently up to workers at
+// a time; within a single peer, processors always run sequentially since
+// later processors may depend on fields an earlier one filled in.
+func Run(ctx context.Context, peers map[string]*config.Peer, cfg *config.Config, workers int) (Summary, error) {
+	steps, err := ordered()
+	if err != nil {
+		return Summary{}, err
+	}
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	var mu sync.Mutex
+	summary := Summary{PerProcessor: map[string]*ProcessorStats{}}
+	for _, p := range steps {
+		summary.PerProcessor[p.Name()] = &ProcessorStats{}
+	}
+
+	names := make(chan string, len(peers))
+	for name := range peers {
+		names <- name
+	}
+	close(names)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for peerName := range names {
+				peer := peers[peerName]
+				skipped := false
+				for _, p := range steps {
+					entry := log.WithFields(log.Fields{"peer": peerName, "processor": p.Name()})
+					if skipped {
+						continue
+					}
+					if err := p.Process(ctx, peer, cfg); err != nil {
+						entry.Warnf("processor failed, skipping remaining pipeline for this peer: %v", err)
+						peer.LastStatus = p.Name() + "-failed"
+						skipped = true
+
+						mu.Lock()
+						summary.PerProcessor[p.Name()].Failed++
+						mu.Unlock()
+						continue
+					}
+					entry.Debug("processor succeeded")
+
+					mu.Lock()
+					summary.PerProcessor[p.Name()].Succeeded++
+					mu.Unlock()
+				}
+
+				mu.Lock()
+				if skipped {
+					summary.PeersSkipped++
+				} else {
+					summary.PeersProcessed++
+					peer.LastStatus = "OK"
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return summary, nil
+}
@@ -0,0 +1,122 @@
+package processors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/natesales/wireframe/config"
+)
+
+type fakeProcessor struct {
+	name  string
+	deps  []string
+	fail  bool
+	calls *int
+}
+
+func (f fakeProcessor) Name() string        { return f.name }
+func (f fakeProcessor) DependsOn() []string { return f.deps }
+
+func (f fakeProcessor) Process(ctx context.Context, peer *config.Peer, cfg *config.Config) error {
+	*f.calls++
+	if f.fail {
+		return errors.New("fake failure")
+	}
+	return nil
+}
+
+func resetRegistry() {
+	registry = map[string]Processor{}
+}
+
+func TestRunSkipsRemainingProcessorsOnFailure(t *testing.T) {
+	resetRegistry()
+	defer resetRegistry()
+
+	var firstCalls, secondCalls int
+	Register(fakeProcessor{name: "first", calls: &firstCalls, fail: true})
+	Register(fakeProcessor{name: "second", deps: []string{"first"}, calls: &secondCalls})
+
+	peers := map[string]*config.Peer{"example": {Asn: 65001}}
+	summary, err := Run(context.Background(), peers, &config.Config{}, 1)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	if firstCalls != 1 {
+		t.Fatalf("expected first processor to run once, got %d", firstCalls)
+	}
+	if secondCalls != 0 {
+		t.Fatalf("expected second processor to be skipped, got %d calls", secondCalls)
+	}
+	if summary.PeersSkipped != 1 || summary.PeersProcessed != 0 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+}
+
+func TestRunProcessesAllPeersIndependently(t *testing.T) {
+	resetRegistry()
+	defer resetRegistry()
+
+	var calls int
+	Register(fakeProcessor{name: "only", calls: &calls})
+
+	peers := map[string]*config.Peer{
+		"a": {Asn: 65001},
+		"b": {Asn: 65002},
+	}
+	summary, err := Run(context.Background(), peers, &config.Config{}, 2)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	if calls != 2 {
+		t.Fatalf("expected processor to run once per peer, got %d", calls)
+	}
+	if summary.PeersProcessed != 2 {
+		t.Fatalf("expected both peers processed, got %+v", summary)
+	}
+}
+
+func TestOrderedIsDeterministicAcrossRegistrations(t *testing.T) {
+	resetRegistry()
+	defer resetRegistry()
+
+	// Three mutually independent processors: nothing about their
+	// DependsOn forces a relative order, so ordered() must fall back to
+	// a stable rule (sorted name) rather than registry map order.
+	Register(fakeProcessor{name: "zebra", calls: new(int)})
+	Register(fakeProcessor{name: "alpha", calls: new(int)})
+	Register(fakeProcessor{name: "mid", calls: new(int)})
+
+	var names []string
+	for i := 0; i < 20; i++ {
+		steps, err := ordered()
+		if err != nil {
+			t.Fatalf("ordered: %v", err)
+		}
+		var got []string
+		for _, p := range steps {
+			got = append(got, p.Name())
+		}
+		if names == nil {
+			names = got
+			continue
+		}
+		if len(got) != len(names) {
+			t.Fatalf("ordered() length changed between calls: %v vs %v", names, got)
+		}
+		for i := range got {
+			if got[i] != names[i] {
+				t.Fatalf("ordered() not deterministic: %v vs %v", names, got)
+			}
+		}
+	}
+	want := []string{"alpha", "mid", "zebra"}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("ordered() = %v, want %v", names, want)
+		}
+	}
+}
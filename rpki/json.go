@@ -0,0 +1,59 @@
+package rpki
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// roaDump is the routinator/rpki-client JSON export format:
+// {"roas":[{"asn":"AS...", "prefix":"...", "maxLength":N}]}.
+type roaDump struct {
+	Roas []struct {
+		Asn       string `json:"asn"`
+		Prefix    string `json:"prefix"`
+		MaxLength int    `json:"maxLength"`
+	} `json:"roas"`
+}
+
+// LoadJSON fetches and parses a ROA dump from url and returns a new Cache
+// holding its VRPs. Used as a fallback when no RtrServer is configured.
+func LoadJSON(url string) (*Cache, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("fetching ROA dump: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var dump roaDump
+	if err := json.Unmarshal(body, &dump); err != nil {
+		return nil, fmt.Errorf("parsing ROA dump: %w", err)
+	}
+
+	vrps := make([]vrp, 0, len(dump.Roas))
+	for _, r := range dump.Roas {
+		_, prefix, err := net.ParseCIDR(r.Prefix)
+		if err != nil {
+			continue
+		}
+		asnStr := strings.TrimPrefix(strings.ToUpper(r.Asn), "AS")
+		asn, err := strconv.ParseUint(asnStr, 10, 32)
+		if err != nil {
+			continue
+		}
+		vrps = append(vrps, vrp{prefix: prefix, maxLen: r.MaxLength, origin: uint32(asn)})
+	}
+
+	c := NewCache()
+	c.Load(vrps)
+	return c, nil
+}
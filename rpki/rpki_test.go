@@ -0,0 +1,69 @@
+package rpki
+
+import (
+	"net"
+	"testing"
+)
+
+func mustParseCIDR(t *testing.T, s string) *net.IPNet {
+	t.Helper()
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		t.Fatalf("parsing %q: %v", s, err)
+	}
+	return n
+}
+
+func TestClassify(t *testing.T) {
+	c := NewCache()
+	c.Load([]vrp{
+		{prefix: mustParseCIDR(t, "203.0.113.0/24"), maxLen: 24, origin: 65001},
+	})
+
+	cases := []struct {
+		name   string
+		prefix string
+		origin uint32
+		want   Status
+	}{
+		{"exact match is valid", "203.0.113.0/24", 65001, Valid},
+		{"wrong origin is invalid", "203.0.113.0/24", 65002, Invalid},
+		{"more specific beyond maxLen is invalid", "203.0.113.0/25", 65001, Invalid},
+		{"uncovered prefix is not found", "198.51.100.0/24", 65001, NotFound},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := c.Classify(mustParseCIDR(t, tc.prefix), tc.origin)
+			if got != tc.want {
+				t.Errorf("Classify(%s, AS%d) = %s, want %s", tc.prefix, tc.origin, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestApplyDeltaRemovesWithdrawnVrps(t *testing.T) {
+	c := NewCache()
+	c.Load([]vrp{
+		{prefix: mustParseCIDR(t, "203.0.113.0/24"), maxLen: 24, origin: 65001},
+		{prefix: mustParseCIDR(t, "198.51.100.0/24"), maxLen: 24, origin: 65002},
+	})
+
+	c.ApplyDelta(
+		[]vrp{{prefix: mustParseCIDR(t, "192.0.2.0/24"), maxLen: 24, origin: 65003}},
+		[]vrp{{prefix: mustParseCIDR(t, "203.0.113.0/24"), maxLen: 24, origin: 65001}},
+	)
+
+	if got := c.Classify(mustParseCIDR(t, "203.0.113.0/24"), 65001); got != NotFound {
+		t.Errorf("withdrawn VRP still classifies as %s, want %s", got, NotFound)
+	}
+	if got := c.Classify(mustParseCIDR(t, "198.51.100.0/24"), 65002); got != Valid {
+		t.Errorf("untouched VRP classifies as %s, want %s", got, Valid)
+	}
+	if got := c.Classify(mustParseCIDR(t, "192.0.2.0/24"), 65003); got != Valid {
+		t.Errorf("newly added VRP classifies as %s, want %s", got, Valid)
+	}
+	if c.Size() != 2 {
+		t.Errorf("Size() = %d, want 2", c.Size())
+	}
+}
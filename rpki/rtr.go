@@ -0,0 +1,217 @@
+package rpki
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// RTR PDU types we handle (RFC 8210).
+const (
+	pduSerialNotify  = 0
+	pduSerialQuery   = 1
+	pduResetQuery    = 2
+	pduCacheResponse = 3
+	pduIPv4Prefix    = 4
+	pduIPv6Prefix    = 6
+	pduEndOfData     = 7
+	pduCacheReset    = 8
+	pduErrorReport   = 10
+)
+
+// Timers are the refresh/retry/expire intervals an RTR server sends in
+// its End of Data PDU (RFC 8210 section 5.8).
+type Timers struct {
+	Refresh time.Duration
+	Retry   time.Duration
+	Expire  time.Duration
+}
+
+// DefaultTimers are used until the server has sent its own.
+var DefaultTimers = Timers{
+	Refresh: 30 * time.Minute,
+	Retry:   5 * time.Minute,
+	Expire:  24 * time.Hour,
+}
+
+// Client is a minimal RFC 8210 RTR client: it connects, performs a Reset
+// Query, and can subsequently issue Serial Queries for incremental
+// updates.
+type Client struct {
+	addr   string
+	dialFn func(network, addr string) (net.Conn, error)
+
+	serial  uint32
+	session uint16
+}
+
+// NewClient returns an RTR client for the given "host:port" address.
+func NewClient(addr string) *Client {
+	return &Client{addr: addr, dialFn: net.Dial}
+}
+
+// Refresh performs a full Reset Query against the server and loads the
+// result into cache, returning the timers the server advertised.
+func (c *Client) Refresh(cache *Cache) (Timers, error) {
+	conn, err := c.dialFn("tcp", c.addr)
+	if err != nil {
+		return DefaultTimers, fmt.Errorf("dial %s: %w", c.addr, err)
+	}
+	defer conn.Close()
+
+	if err := writePDU(conn, pduResetQuery, 0, nil); err != nil {
+		return DefaultTimers, err
+	}
+
+	return c.readUpdate(conn, cache, true)
+}
+
+// SerialRefresh issues a Serial Query using the last-seen serial number,
+// applying the incremental add/remove PDUs the server sends back.
+func (c *Client) SerialRefresh(cache *Cache) (Timers, error) {
+	conn, err := c.dialFn("tcp", c.addr)
+	if err != nil {
+		return DefaultTimers, fmt.Errorf("dial %s: %w", c.addr, err)
+	}
+	defer conn.Close()
+
+	payload := make([]byte, 4)
+	binary.BigEndian.PutUint32(payload, c.serial)
+	if err := writePDU(conn, pduSerialQuery, c.session, payload); err != nil {
+		return DefaultTimers, err
+	}
+
+	return c.readUpdate(conn, cache, false)
+}
+
+// readUpdate reads PDUs until End of Data / Cache Reset, applying VRP
+// adds (and, for serial updates, removes) to cache.
+func (c *Client) readUpdate(conn net.Conn, cache *Cache, full bool) (Timers, error) {
+	timers := DefaultTimers
+	var adds, removes []vrp
+
+	for {
+		pduType, sessionID, payload, err := readPDU(conn)
+		if err != nil {
+			return timers, err
+		}
+
+		switch pduType {
+		case pduCacheResponse:
+			c.session = sessionID
+
+		case pduIPv4Prefix, pduIPv6Prefix:
+			v, announce, err := decodePrefixPDU(pduType, payload)
+			if err != nil {
+				return timers, err
+			}
+			if announce {
+				adds = append(adds, v)
+			} else {
+				removes = append(removes, v)
+			}
+
+		case pduCacheReset:
+			// Server can't serve an incremental update from our serial;
+			// the caller should fall back to a full Reset Query.
+			return timers, fmt.Errorf("rpki: server sent Cache Reset, full refresh required")
+
+		case pduEndOfData:
+			if len(payload) >= 12 {
+				c.serial = binary.BigEndian.Uint32(payload[0:4])
+				timers.Refresh = time.Duration(binary.BigEndian.Uint32(payload[4:8])) * time.Second
+				timers.Retry = time.Duration(binary.BigEndian.Uint32(payload[8:12])) * time.Second
+			}
+			if len(payload) >= 16 {
+				timers.Expire = time.Duration(binary.BigEndian.Uint32(payload[12:16])) * time.Second
+			}
+			if full {
+				cache.Load(adds)
+			} else {
+				cache.ApplyDelta(adds, removes)
+			}
+			return timers, nil
+
+		case pduErrorReport:
+			return timers, fmt.Errorf("rpki: server error report")
+
+		default:
+			return timers, fmt.Errorf("rpki: unexpected PDU type %d", pduType)
+		}
+	}
+}
+
+// writePDU writes an RTR protocol version 1 PDU: 1 byte version, 1 byte
+// type, 2 bytes session ID/reserved, 4 bytes length, then payload.
+func writePDU(w io.Writer, pduType uint8, sessionID uint16, payload []byte) error {
+	header := make([]byte, 8)
+	header[0] = 1 // protocol version
+	header[1] = pduType
+	binary.BigEndian.PutUint16(header[2:4], sessionID)
+	binary.BigEndian.PutUint32(header[4:8], uint32(8+len(payload)))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if len(payload) > 0 {
+		_, err := w.Write(payload)
+		return err
+	}
+	return nil
+}
+
+func readPDU(r io.Reader) (pduType uint8, sessionID uint16, payload []byte, err error) {
+	header := make([]byte, 8)
+	if _, err = io.ReadFull(r, header); err != nil {
+		return 0, 0, nil, err
+	}
+	pduType = header[1]
+	sessionID = binary.BigEndian.Uint16(header[2:4])
+	length := binary.BigEndian.Uint32(header[4:8])
+	if length < 8 {
+		return 0, 0, nil, fmt.Errorf("rpki: malformed PDU length %d", length)
+	}
+	payload = make([]byte, length-8)
+	if len(payload) > 0 {
+		if _, err = io.ReadFull(r, payload); err != nil {
+			return 0, 0, nil, err
+		}
+	}
+	return pduType, sessionID, payload, nil
+}
+
+// decodePrefixPDU parses an IPv4/IPv6 Prefix PDU (RFC 8210 sections
+// 5.6/5.7), returning the decoded VRP and whether it's an announcement
+// (true) or a withdrawal (false) per the low bit of the Flags octet.
+func decodePrefixPDU(pduType uint8, payload []byte) (vrp, bool, error) {
+	if pduType == pduIPv4Prefix {
+		if len(payload) < 12 {
+			return vrp{}, false, fmt.Errorf("rpki: short IPv4 prefix PDU")
+		}
+		announce := payload[0]&1 != 0
+		prefixLen := payload[1]
+		maxLen := payload[2]
+		ip := net.IPv4(payload[4], payload[5], payload[6], payload[7])
+		asn := binary.BigEndian.Uint32(payload[8:12])
+		return vrp{
+			prefix: &net.IPNet{IP: ip, Mask: net.CIDRMask(int(prefixLen), 32)},
+			maxLen: int(maxLen),
+			origin: asn,
+		}, announce, nil
+	}
+
+	if len(payload) < 24 {
+		return vrp{}, false, fmt.Errorf("rpki: short IPv6 prefix PDU")
+	}
+	announce := payload[0]&1 != 0
+	prefixLen := payload[1]
+	maxLen := payload[2]
+	ip := net.IP(payload[4:20])
+	asn := binary.BigEndian.Uint32(payload[20:24])
+	return vrp{
+		prefix: &net.IPNet{IP: ip, Mask: net.CIDRMask(int(prefixLen), 128)},
+		maxLen: int(maxLen),
+		origin: asn,
+	}, announce, nil
+}
@@ -0,0 +1,41 @@
+// Package secretgen generates cryptographically strong BGP session
+// secrets (MD5 passwords, BFD auth keys).
+package secretgen
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+// DefaultAlphabet is letters and digits, a safe default for MD5/BFD
+// shared secrets that avoids characters BIRD or router vendors might
+// treat specially.
+const DefaultAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+// DefaultLength is the generated secret length in characters.
+const DefaultLength = 32
+
+// Generate returns a random string of length characters drawn from
+// alphabet, suitable for use as a session secret.
+func Generate(alphabet string, length int) (string, error) {
+	if alphabet == "" {
+		alphabet = DefaultAlphabet
+	}
+	if length <= 0 {
+		length = DefaultLength
+	}
+
+	// rand.Int draws uniformly from [0, len(alphabet)) via rejection
+	// sampling internally, avoiding the modulo bias a raw byte%len
+	// would introduce whenever len(alphabet) doesn't divide 256.
+	n := big.NewInt(int64(len(alphabet)))
+	out := make([]byte, length)
+	for i := range out {
+		idx, err := rand.Int(rand.Reader, n)
+		if err != nil {
+			return "", err
+		}
+		out[i] = alphabet[idx.Int64()]
+	}
+	return string(out), nil
+}
@@ -0,0 +1,33 @@
+package secretgen
+
+import "testing"
+
+func TestGenerateUsesFullAlphabet(t *testing.T) {
+	// With a small alphabet and enough draws, every character should
+	// turn up at least once; a modulo-biased generator would
+	// systematically favor a prefix of the alphabet instead.
+	const alphabet = "ab"
+	seen := map[byte]bool{}
+	for i := 0; i < 200; i++ {
+		s, err := Generate(alphabet, 1)
+		if err != nil {
+			t.Fatalf("Generate: %v", err)
+		}
+		seen[s[0]] = true
+	}
+	for _, c := range []byte(alphabet) {
+		if !seen[c] {
+			t.Errorf("character %q never generated in 200 draws", c)
+		}
+	}
+}
+
+func TestGenerateLength(t *testing.T) {
+	s, err := Generate("", 0)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if len(s) != DefaultLength {
+		t.Errorf("len(Generate(\"\", 0)) = %d, want %d", len(s), DefaultLength)
+	}
+}
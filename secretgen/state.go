@@ -0,0 +1,79 @@
+package secretgen
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// State is the on-disk record of generated session secrets, keyed by
+// "<peerName>.<field>" so the same value is reused across config
+// regenerations instead of rotating on every run.
+type State struct {
+	path    string
+	mu      sync.Mutex
+	secrets map[string]string
+}
+
+// NewState returns an empty State backed by path, without reading it.
+func NewState(path string) *State {
+	return &State{path: path, secrets: map[string]string{}}
+}
+
+// LoadState reads path, or returns an empty State if it doesn't exist
+// yet (the first run for a config that has no AUTO secrets so far).
+func LoadState(path string) (*State, error) {
+	data, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewState(path), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := map[string]string{}
+	if err := yaml.Unmarshal(data, &secrets); err != nil {
+		return nil, err
+	}
+	return &State{path: path, secrets: secrets}, nil
+}
+
+// Get returns the persisted secret for (peerName, field), if any.
+func (s *State) Get(peerName, field string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.secrets[stateKey(peerName, field)]
+	return v, ok
+}
+
+// Set records value for (peerName, field) and persists the state file
+// atomically (temp file + rename) with mode 0600, since it holds live
+// session secrets.
+func (s *State) Set(peerName, field, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.secrets[stateKey(peerName, field)] = value
+
+	data, err := yaml.Marshal(s.secrets)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+
+	tmp := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func stateKey(peerName, field string) string {
+	return peerName + "." + field
+}
@@ -0,0 +1,38 @@
+package secretgen
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestStateRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.yaml")
+
+	s := NewState(path)
+	if _, ok := s.Get("example", "MD5Password"); ok {
+		t.Fatal("expected no secret before Set")
+	}
+
+	if err := s.Set("example", "MD5Password", "hunter2"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	reloaded, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+	got, ok := reloaded.Get("example", "MD5Password")
+	if !ok || got != "hunter2" {
+		t.Errorf("Get after reload = (%q, %v), want (\"hunter2\", true)", got, ok)
+	}
+}
+
+func TestLoadStateMissingFileIsEmpty(t *testing.T) {
+	s, err := LoadState(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+	if _, ok := s.Get("example", "MD5Password"); ok {
+		t.Error("expected no secrets in a freshly-missing state file")
+	}
+}
@@ -0,0 +1,59 @@
+package statusz
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// These are simple package-level accumulators rather than a full metrics
+// library: pathvector only needs a handful of gauges/counters, and
+// taking a dependency on a metrics client just to expose them to
+// /metrics would be overkill.
+var (
+	metricsMu         sync.Mutex
+	irrQueryDurations float64 // sum of seconds, for pathvector_irr_query_duration_seconds
+	irrQueryCount     uint64
+	cacheFreshness    = map[string]time.Time{} // cache name -> last refresh time
+)
+
+// RecordIrrQueryDuration accumulates an IRR query's wall-clock duration
+// into the pathvector_irr_query_duration_seconds metric.
+func RecordIrrQueryDuration(d time.Duration) {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	irrQueryDurations += d.Seconds()
+	irrQueryCount++
+}
+
+// SetCacheFreshness records that the named cache (e.g. "irr", "rpki")
+// was last refreshed at t.
+func SetCacheFreshness(name string, t time.Time) {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	cacheFreshness[name] = t
+}
+
+func writeIrrQueryDuration(w io.Writer) {
+	metricsMu.Lock()
+	sum, count := irrQueryDurations, irrQueryCount
+	metricsMu.Unlock()
+
+	// _sum/_count with no quantiles is a summary with zero observed
+	// quantiles, not a counter - Prometheus reserves the bare metric
+	// name for a counter's own series, which this doesn't expose.
+	fmt.Fprint(w, "# HELP pathvector_irr_query_duration_seconds Cumulative time spent querying IRR\n# TYPE pathvector_irr_query_duration_seconds summary\n")
+	fmt.Fprintf(w, "pathvector_irr_query_duration_seconds_sum %f\n", sum)
+	fmt.Fprintf(w, "pathvector_irr_query_duration_seconds_count %d\n", count)
+}
+
+func writeCacheFreshness(w io.Writer) {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+
+	fmt.Fprint(w, "# HELP pathvector_cache_age_seconds Seconds since a cache was last refreshed\n# TYPE pathvector_cache_age_seconds gauge\n")
+	for name, t := range cacheFreshness {
+		fmt.Fprintf(w, "pathvector_cache_age_seconds{cache=%q} %f\n", name, time.Since(t).Seconds())
+	}
+}
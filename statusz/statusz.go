@@ -0,0 +1,107 @@
+// Package statusz exposes a small HTTP server for runtime introspection:
+// /statusz (an HTML dashboard), /healthz, and /metrics (Prometheus text
+// exposition format).
+package statusz
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+)
+
+// PeerStatus is the per-peer information shown on /statusz and counted
+// into /metrics.
+type PeerStatus struct {
+	Name          string
+	Status        string
+	LastProcessed time.Time
+	Prefixes4     int
+	Prefixes6     int
+	RpkiInvalid   int
+}
+
+// Snapshot is everything /statusz and /metrics render. It's produced
+// fresh on every request by the Provider func passed to NewServer, so
+// readers always see the most recent reconfigure.
+type Snapshot struct {
+	Version              string
+	ConfigPath           string
+	ConfigModTime        time.Time
+	LastReconfigure      string
+	LastReconfigureError string
+	Peers                []PeerStatus
+}
+
+// Provider returns the current Snapshot. Implementations must be safe
+// to call concurrently with a reconfigure in progress.
+type Provider func() Snapshot
+
+// Server serves /statusz, /healthz and /metrics.
+type Server struct {
+	provider Provider
+	mux      *http.ServeMux
+}
+
+// NewServer returns a Server backed by provider.
+func NewServer(provider Provider) *Server {
+	s := &Server{provider: provider, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/statusz", s.handleStatusz)
+	s.mux.HandleFunc("/healthz", s.handleHealthz)
+	s.mux.HandleFunc("/metrics", s.handleMetrics)
+	return s
+}
+
+// ListenAndServe serves on addr until it errors; callers typically run
+// this in its own goroutine.
+func (s *Server) ListenAndServe(addr string) error {
+	return http.ListenAndServe(addr, s.mux)
+}
+
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("ok"))
+}
+
+var statuszTemplate = template.Must(template.New("statusz").Parse(`<!DOCTYPE html>
+<html>
+<head><title>pathvector statusz</title></head>
+<body>
+<h1>pathvector {{.Version}}</h1>
+<p>Config: {{.ConfigPath}} (modified {{.ConfigModTime}})</p>
+<p>Last reconfigure: {{.LastReconfigure}}{{if .LastReconfigureError}} ({{.LastReconfigureError}}){{end}}</p>
+<table border="1" cellpadding="4">
+<tr><th>Peer</th><th>Status</th><th>Last processed</th><th>IPv4 prefixes</th><th>IPv6 prefixes</th><th>RPKI invalid</th></tr>
+{{range .Peers}}<tr><td>{{.Name}}</td><td>{{.Status}}</td><td>{{.LastProcessed}}</td><td>{{.Prefixes4}}</td><td>{{.Prefixes6}}</td><td>{{.RpkiInvalid}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+func (s *Server) handleStatusz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := statuszTemplate.Execute(w, s.provider()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	snap := s.provider()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	fmt.Fprintf(w, "# HELP pathvector_peers Number of configured peers\n# TYPE pathvector_peers gauge\npathvector_peers %d\n", len(snap.Peers))
+
+	fmt.Fprint(w, "# HELP pathvector_peer_prefixes Resolved prefix-set size per peer and family\n# TYPE pathvector_peer_prefixes gauge\n")
+	for _, p := range snap.Peers {
+		fmt.Fprintf(w, "pathvector_peer_prefixes{peer=%q,family=\"4\"} %d\n", p.Name, p.Prefixes4)
+		fmt.Fprintf(w, "pathvector_peer_prefixes{peer=%q,family=\"6\"} %d\n", p.Name, p.Prefixes6)
+	}
+
+	invalid := 0
+	for _, p := range snap.Peers {
+		invalid += p.RpkiInvalid
+	}
+	fmt.Fprintf(w, "# HELP pathvector_rpki_invalid Number of RPKI-invalid prefixes dropped on the last run\n# TYPE pathvector_rpki_invalid gauge\npathvector_rpki_invalid %d\n", invalid)
+
+	writeIrrQueryDuration(w)
+	writeCacheFreshness(w)
+}
@@ -0,0 +1,45 @@
+package statusz
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHealthz(t *testing.T) {
+	srv := NewServer(func() Snapshot { return Snapshot{} })
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	rec := httptest.NewRecorder()
+	srv.mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if rec.Body.String() != "ok" {
+		t.Errorf("body = %q, want \"ok\"", rec.Body.String())
+	}
+}
+
+func TestMetricsIncludesPeerGauges(t *testing.T) {
+	srv := NewServer(func() Snapshot {
+		return Snapshot{Peers: []PeerStatus{{Name: "example", Prefixes4: 3, Prefixes6: 1, RpkiInvalid: 2}}}
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rec := httptest.NewRecorder()
+	srv.mux.ServeHTTP(rec, req)
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		`pathvector_peer_prefixes{peer="example",family="4"} 3`,
+		`pathvector_peer_prefixes{peer="example",family="6"} 1`,
+		"pathvector_rpki_invalid 2",
+		"pathvector_peers 1",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output missing %q\ngot:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import "github.com/natesales/wireframe/statusz"
+
+// statuszSnapshot is the statusz.Provider backing the --statusz-listen
+// server; it reads the most recently applied config under a read lock
+// so it stays safe to call while a --daemon reconfigure is in flight.
+func statuszSnapshot() statusz.Snapshot {
+	currentConfigMu.RLock()
+	defer currentConfigMu.RUnlock()
+
+	snap := statusz.Snapshot{
+		Version:              version,
+		LastReconfigure:      lastReconfigure,
+		LastReconfigureError: lastReconfigErr,
+	}
+
+	if currentConfig == nil {
+		return snap
+	}
+
+	snap.ConfigPath = currentConfig.ConfigPath
+	snap.ConfigModTime = currentConfig.ConfigModTime
+	for name, p := range currentConfig.Peers {
+		snap.Peers = append(snap.Peers, statusz.PeerStatus{
+			Name:          name,
+			Status:        p.LastStatus,
+			LastProcessed: p.LastProcessed,
+			Prefixes4:     len(p.PrefixSet4),
+			Prefixes6:     len(p.PrefixSet6),
+			RpkiInvalid:   p.RpkiInvalidCount,
+		})
+	}
+	return snap
+}
@@ -0,0 +1,20 @@
+package main
+
+import "regexp"
+
+var nameSanitizer = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// contains reports whether s is present in list.
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitize strips characters that aren't valid in a BIRD protocol name.
+func sanitize(s string) string {
+	return nameSanitizer.ReplaceAllString(s, "_")
+}